@@ -0,0 +1,161 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// edgeMetricFields whitelists the edge fields /edges/top may aggregate on.
+var edgeMetricFields = map[string]string{
+	"latency":      "latency",
+	"frequency":    "frequency",
+	"co_execution": "co_execution",
+}
+
+// nodeSeriesFields whitelists the node fields /nodes/{id}/series may return.
+var nodeSeriesFields = map[string]string{
+	"absolute_importance": "absolute_importance",
+	"absolute_dependence": "absolute_dependence",
+}
+
+// MongoStore implements Store over the collector's Metrics collection.
+type MongoStore struct {
+	Collection *mongo.Collection
+}
+
+// NewMongoStore wraps col as a Store.
+func NewMongoStore(col *mongo.Collection) *MongoStore {
+	return &MongoStore{Collection: col}
+}
+
+func (s *MongoStore) Nearest(ctx context.Context, at int64) (GraphSnapshot, error) {
+	containing := bson.M{"start_time": bson.M{"$lte": at}, "end_time": bson.M{"$gte": at}}
+	var snapshot GraphSnapshot
+	err := s.Collection.FindOne(ctx, containing).Decode(&snapshot)
+	if err == nil {
+		return snapshot, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return GraphSnapshot{}, fmt.Errorf("querying nearest snapshot: %w", err)
+	}
+
+	// No snapshot contains `at`; fall back to whichever window ended closest
+	// to it, searching forward and backward and keeping the nearer of the two.
+	before := options.FindOne().SetSort(bson.D{{Key: "end_time", Value: -1}})
+	var prev GraphSnapshot
+	prevErr := s.Collection.FindOne(ctx, bson.M{"end_time": bson.M{"$lte": at}}, before).Decode(&prev)
+
+	after := options.FindOne().SetSort(bson.D{{Key: "start_time", Value: 1}})
+	var next GraphSnapshot
+	nextErr := s.Collection.FindOne(ctx, bson.M{"start_time": bson.M{"$gte": at}}, after).Decode(&next)
+
+	switch {
+	case prevErr == nil && nextErr == nil:
+		if at-prev.EndTime <= next.StartTime-at {
+			return prev, nil
+		}
+		return next, nil
+	case prevErr == nil:
+		return prev, nil
+	case nextErr == nil:
+		return next, nil
+	default:
+		return GraphSnapshot{}, mongo.ErrNoDocuments
+	}
+}
+
+func (s *MongoStore) Range(ctx context.Context, from, to int64, yield func(GraphSnapshot) error) error {
+	filter := bson.M{"start_time": bson.M{"$gte": from}, "end_time": bson.M{"$lte": to}}
+	opts := options.Find().SetSort(bson.D{{Key: "start_time", Value: 1}})
+
+	cur, err := s.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		return fmt.Errorf("querying range: %w", err)
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var snapshot GraphSnapshot
+		if err := cur.Decode(&snapshot); err != nil {
+			return fmt.Errorf("decoding snapshot: %w", err)
+		}
+		if err := yield(snapshot); err != nil {
+			return err
+		}
+	}
+	return cur.Err()
+}
+
+func (s *MongoStore) TopEdges(ctx context.Context, metric string, limit int) ([]EdgeAggregate, error) {
+	field, ok := edgeMetricFields[metric]
+	if !ok {
+		return nil, fmt.Errorf("unsupported edge metric %q", metric)
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$unwind", Value: "$data.edges"}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"source": "$data.edges.source", "target": "$data.edges.target"},
+			"value": bson.M{"$sum": "$data.edges." + field},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "value", Value: -1}}}},
+		{{Key: "$limit", Value: limit}},
+	}
+
+	cur, err := s.Collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("aggregating top edges: %w", err)
+	}
+	defer cur.Close(ctx)
+
+	var rows []struct {
+		ID struct {
+			Source string `bson:"source"`
+			Target string `bson:"target"`
+		} `bson:"_id"`
+		Value float64 `bson:"value"`
+	}
+	if err := cur.All(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("decoding top edges: %w", err)
+	}
+
+	out := make([]EdgeAggregate, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, EdgeAggregate{Source: r.ID.Source, Target: r.ID.Target, Value: r.Value})
+	}
+	return out, nil
+}
+
+func (s *MongoStore) NodeSeries(ctx context.Context, nodeID, field string) ([]SeriesPoint, error) {
+	nodeField, ok := nodeSeriesFields[field]
+	if !ok {
+		return nil, fmt.Errorf("unsupported node field %q", field)
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"data.nodes.id": nodeID}}},
+		{{Key: "$unwind", Value: "$data.nodes"}},
+		{{Key: "$match", Value: bson.M{"data.nodes.id": nodeID}}},
+		{{Key: "$project", Value: bson.M{
+			"timestamp": "$end_time",
+			"value":     "$data.nodes." + nodeField,
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "timestamp", Value: 1}}}},
+	}
+
+	cur, err := s.Collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("aggregating node series: %w", err)
+	}
+	defer cur.Close(ctx)
+
+	var points []SeriesPoint
+	if err := cur.All(ctx, &points); err != nil {
+		return nil, fmt.Errorf("decoding node series: %w", err)
+	}
+	return points, nil
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Server exposes Store over HTTP.
+type Server struct {
+	Store Store
+}
+
+// NewServer builds a Server over store.
+func NewServer(store Store) *Server {
+	return &Server{Store: store}
+}
+
+// Routes returns the server's http.Handler.
+func (s *Server) Routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graph", s.handleGraph)
+	mux.HandleFunc("/graph/range", s.handleGraphRange)
+	mux.HandleFunc("/edges/top", s.handleTopEdges)
+	mux.HandleFunc("/nodes/", s.handleNodeSeries)
+	return mux
+}
+
+// Serve starts the read API on addr and returns the underlying *http.Server
+// so the caller can shut it down gracefully. It does not block.
+func Serve(addr string, store Store) *http.Server {
+	srv := &http.Server{
+		Addr:         addr,
+		Handler:      NewServer(store).Routes(),
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 15 * time.Second,
+	}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.Error("api server stopped", "error", err)
+		}
+	}()
+	return srv
+}
+
+func (s *Server) handleGraph(w http.ResponseWriter, r *http.Request) {
+	at, err := strconv.ParseInt(r.URL.Query().Get("at"), 10, 64)
+	if err != nil {
+		http.Error(w, "at must be a unix microsecond timestamp", http.StatusBadRequest)
+		return
+	}
+
+	snapshot, err := s.Store.Nearest(r.Context(), at)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, snapshot)
+}
+
+func (s *Server) handleGraphRange(w http.ResponseWriter, r *http.Request) {
+	from, err1 := strconv.ParseInt(r.URL.Query().Get("from"), 10, 64)
+	to, err2 := strconv.ParseInt(r.URL.Query().Get("to"), 10, 64)
+	if err1 != nil || err2 != nil || to <= from {
+		http.Error(w, "from and to must be unix microsecond timestamps with to after from", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	enc := json.NewEncoder(w)
+	err := s.Store.Range(r.Context(), from, to, func(snapshot GraphSnapshot) error {
+		return enc.Encode(snapshot)
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *Server) handleTopEdges(w http.ResponseWriter, r *http.Request) {
+	metric := r.URL.Query().Get("metric")
+	limit := 10
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			limit = n
+		}
+	}
+
+	edges, err := s.Store.TopEdges(r.Context(), metric, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, edges)
+}
+
+func (s *Server) handleNodeSeries(w http.ResponseWriter, r *http.Request) {
+	// Path shape: /nodes/{id}/series
+	id, ok := parseNodeSeriesPath(r.URL.Path)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	field := r.URL.Query().Get("field")
+	series, err := s.Store.NodeSeries(r.Context(), id, field)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, series)
+}
+
+func parseNodeSeriesPath(path string) (id string, ok bool) {
+	const prefix = "/nodes/"
+	const suffix = "/series"
+	if len(path) <= len(prefix)+len(suffix) {
+		return "", false
+	}
+	if path[:len(prefix)] != prefix || path[len(path)-len(suffix):] != suffix {
+		return "", false
+	}
+	return path[len(prefix) : len(path)-len(suffix)], true
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
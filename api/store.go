@@ -0,0 +1,27 @@
+package api
+
+import "context"
+
+// Store is the read side of the collector: it serves stored graph snapshots
+// and aggregations over them. Kept separate from the write path (Container's
+// saveMetrics) so handlers can be tested against a mock Store instead of a
+// live MongoDB.
+type Store interface {
+	// Nearest returns the snapshot whose window is closest to at (unix
+	// microseconds).
+	Nearest(ctx context.Context, at int64) (GraphSnapshot, error)
+
+	// Range streams every snapshot whose window falls within [from, to]
+	// (both ends inclusive), ordered by start time, calling yield once per
+	// snapshot. Iteration stops early if yield returns an error.
+	Range(ctx context.Context, from, to int64, yield func(GraphSnapshot) error) error
+
+	// TopEdges aggregates the given edge metric ("latency", "frequency", or
+	// "co_execution") across all stored snapshots and returns the top limit
+	// edges by value.
+	TopEdges(ctx context.Context, metric string, limit int) ([]EdgeAggregate, error)
+
+	// NodeSeries returns the time series of a node field ("absolute_importance"
+	// or "absolute_dependence") across all snapshots containing that node.
+	NodeSeries(ctx context.Context, nodeID, field string) ([]SeriesPoint, error)
+}
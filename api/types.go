@@ -0,0 +1,47 @@
+package api
+
+import "time"
+
+// GraphSnapshot mirrors one stored Metrics document for API responses.
+type GraphSnapshot struct {
+	StartTime int64     `json:"start_time" bson:"start_time"`
+	EndTime   int64     `json:"end_time" bson:"end_time"`
+	Data      GraphData `json:"data" bson:"data"`
+	// StoredAt is when the snapshot was written, used only to drive the
+	// metrics collection's TTL index.
+	StoredAt time.Time `json:"stored_at" bson:"stored_at"`
+}
+
+// GraphData is the dependency graph captured for a single window.
+type GraphData struct {
+	Nodes []Node `json:"nodes" bson:"nodes"`
+	Edges []Edge `json:"edges" bson:"edges"`
+}
+
+type Node struct {
+	ID                 string `json:"id" bson:"id"`
+	AbsoluteImportance int    `json:"absolute_importance" bson:"absolute_importance"`
+	AbsoluteDependence int    `json:"absolute_dependence" bson:"absolute_dependence"`
+}
+
+type Edge struct {
+	Source      string  `json:"source" bson:"source"`
+	Target      string  `json:"target" bson:"target"`
+	Latency     float64 `json:"latency" bson:"latency"`
+	Frequency   int     `json:"frequency" bson:"frequency"`
+	CoExecution float64 `json:"co_execution" bson:"co_execution"`
+}
+
+// EdgeAggregate is one row of a /edges/top response: an edge and the value
+// of the requested metric, summed across all stored snapshots.
+type EdgeAggregate struct {
+	Source string  `json:"source"`
+	Target string  `json:"target"`
+	Value  float64 `json:"value"`
+}
+
+// SeriesPoint is one sample of a /nodes/{id}/series response.
+type SeriesPoint struct {
+	Timestamp int64   `json:"timestamp"`
+	Value     float64 `json:"value"`
+}
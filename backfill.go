@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/coupling-index-monitor/metric-n-trace-collector/metrics"
+	"github.com/coupling-index-monitor/metric-n-trace-collector/retry"
+	mongostore "github.com/coupling-index-monitor/metric-n-trace-collector/store/mongo"
+)
+
+// defaultBackfillWindow is the sub-window size a large backfill range is
+// split into when none is given on the command line.
+const defaultBackfillWindow = 15 * time.Minute
+
+// defaultBackfillConcurrency bounds how many sub-windows are fetched at once.
+const defaultBackfillConcurrency = 4
+
+// backfillWindow is one [start,end) sub-range of a backfill run, in unix
+// microseconds, matching the Metrics document fields.
+type backfillWindow struct {
+	start int64
+	end   int64
+}
+
+// runBackfillCommand implements `main backfill --from <ts> --to <ts>`,
+// running the same fetch/save pipeline as the cron tick but out-of-band and
+// split into bounded sub-windows.
+func runBackfillCommand(args []string) {
+	fs := flag.NewFlagSet("backfill", flag.ExitOnError)
+	from := fs.Int64("from", 0, "start of the backfill range, unix microseconds")
+	to := fs.Int64("to", 0, "end of the backfill range, unix microseconds")
+	window := fs.Duration("window", defaultBackfillWindow, "sub-window size to split the range into")
+	concurrency := fs.Int("concurrency", defaultBackfillConcurrency, "number of sub-windows fetched concurrently")
+	if err := fs.Parse(args); err != nil {
+		os.Exit(2)
+	}
+
+	if *from <= 0 || *to <= 0 || *to <= *from {
+		slog.Error("backfill requires --from and --to, with --to after --from")
+		os.Exit(2)
+	}
+
+	container, mongoClient, err := buildContainer()
+	if err != nil {
+		slog.Error("Startup failed", "error", err)
+		os.Exit(1)
+	}
+	defer func() {
+		_ = container.MetricsSink.Close()
+		_ = mongostore.DisconnectWithTimeout(mongoClient, 10*time.Second)
+	}()
+
+	logger := slog.With("fetch_id", newFetchID())
+	if err := container.Backfill(*from, *to, *window, *concurrency, logger); err != nil {
+		slog.Error("Backfill failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+// Backfill splits [from,to) into sequential sub-windows and fetches them
+// through a bounded worker pool. Each sub-window is saved as its own Metrics
+// document; a window that fails is durably enqueued to the retry queue (or
+// dead-lettered) before fetchAndStoreWindow returns, so by the time every
+// goroutine finishes, the whole range has a recorded disposition — stored,
+// queued for retry, or dead-lettered.
+//
+// UpdateLog therefore advances across the entire range rather than only the
+// contiguous prefix of successes: once a window's fate is durable, leaving
+// it uncovered by UpdateLog buys nothing (a later cron tick can't "retry"
+// it any better than the retry queue already will) and costs a lot (every
+// later tick re-fetches and re-stores every window after the stuck one).
+// This mirrors processWindow/enqueueRetry, which advance past a window as
+// soon as its failure is durably queued rather than waiting on success.
+func (c *Container) Backfill(from, to int64, window time.Duration, concurrency int, logger *slog.Logger) error {
+	windowMicros := window.Microseconds()
+	if windowMicros <= 0 {
+		return fmt.Errorf("window must be positive")
+	}
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	var windows []backfillWindow
+	for s := from; s < to; s += windowMicros {
+		e := s + windowMicros
+		if e > to {
+			e = to
+		}
+		windows = append(windows, backfillWindow{start: s, end: e})
+	}
+	logger.Info("Starting backfill", "from", from, "to", to, "windows", len(windows), "concurrency", concurrency)
+
+	results := make([]bool, len(windows))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, w := range windows {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, w backfillWindow) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = c.fetchAndStoreWindow(w.start, w.end, logger)
+		}(i, w)
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, ok := range results {
+		if !ok {
+			failed++
+		}
+	}
+
+	if len(windows) > 0 {
+		if err := c.advanceUpdateLog(to); err != nil {
+			return fmt.Errorf("advancing update log after backfill: %w", err)
+		}
+		logger.Info("Backfill advanced update log", "last_fetch_time", to)
+	}
+	if failed > 0 {
+		logger.Info("Backfill finished with failed windows queued for retry", "failed", failed, "total", len(windows))
+	} else {
+		logger.Info("Backfill complete", "total", len(windows))
+	}
+	return nil
+}
+
+// fetchAndStoreWindow fetches and persists a single backfill sub-window,
+// enqueueing it to the durable retry queue on terminal failure. It reports
+// whether the window was successfully persisted or skipped as empty.
+func (c *Container) fetchAndStoreWindow(start, end int64, logger *slog.Logger) bool {
+	logger = logger.With("start", start, "end", end)
+
+	var graphData GraphData
+	fetchStart := time.Now()
+	err := retry.Default.Do(context.Background(), perTickAttempts, func() error {
+		var fetchErr error
+		graphData, fetchErr = c.fetchGraphData(start, end, logger)
+		return fetchErr
+	})
+	metrics.FetchDuration.Observe(time.Since(fetchStart).Seconds())
+	if err != nil {
+		metrics.FetchTotal.WithLabelValues("error").Inc()
+		logger.Error("Backfill window fetch failed", "error", err)
+		c.enqueueRetry(start, end, 0, err, logger)
+		return false
+	}
+	metrics.FetchTotal.WithLabelValues("success").Inc()
+	metrics.GraphNodes.Set(float64(len(graphData.Nodes)))
+	metrics.GraphEdges.Set(float64(len(graphData.Edges)))
+	metrics.ObserveGap(start, end)
+
+	if len(graphData.Nodes) == 0 || len(graphData.Edges) == 0 {
+		logger.Info("Empty graph data for backfill window — skipping")
+		return true
+	}
+
+	doc := Metrics{StartTime: start, EndTime: end, Data: graphData}
+	err = retry.Default.Do(context.Background(), perTickAttempts, func() error {
+		return c.insertMetricsDoc(doc)
+	})
+	if err != nil {
+		logger.Error("Backfill window save failed", "error", err)
+		c.enqueueRetry(start, end, 0, err, logger)
+		return false
+	}
+
+	return true
+}
@@ -2,16 +2,24 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
+	"github.com/coupling-index-monitor/metric-n-trace-collector/api"
+	"github.com/coupling-index-monitor/metric-n-trace-collector/metrics"
+	"github.com/coupling-index-monitor/metric-n-trace-collector/retry"
+	"github.com/coupling-index-monitor/metric-n-trace-collector/sink"
+	mongostore "github.com/coupling-index-monitor/metric-n-trace-collector/store/mongo"
 	"github.com/joho/godotenv"
 	"github.com/robfig/cron/v3"
 	"go.mongodb.org/mongo-driver/bson"
@@ -19,6 +27,23 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// defaultMaxRetryAttempts is used when MAX_RETRY_ATTEMPTS is unset or invalid.
+const defaultMaxRetryAttempts = 5
+
+// perTickAttempts bounds the immediate in-process retries (via retry.Backoff)
+// before a window is handed off to the durable retry queue.
+const perTickAttempts = 3
+
+// defaultMetricsAddr is used when METRICS_ADDR is unset.
+const defaultMetricsAddr = ":9090"
+
+// defaultAPIAddr is used when API_ADDR is unset.
+const defaultAPIAddr = ":8080"
+
+// defaultBackfillConcurrencyTick bounds sub-window concurrency for the
+// automatic in-cron backfill, independent of the CLI's --concurrency flag.
+const defaultBackfillConcurrencyTick = defaultBackfillConcurrency
+
 type GraphData struct {
 	Nodes []struct {
 		ID                 string `json:"id"`
@@ -53,30 +78,106 @@ type UpdateLog struct {
 }
 
 type Container struct {
-	GraphAPIURL         string
-	DatabaseName        string
-	MetricsCollection   string
-	UpdateLogCollection string
-	MongoClient         *mongo.Client
-	HTTPClient          *http.Client
+	GraphAPIURL          string
+	DatabaseName         string
+	MetricsCollection    string
+	UpdateLogCollection  string
+	RetryQueueCollection string
+	DeadLetterCollection string
+	MaxRetryAttempts     int
+	BackfillWindow       time.Duration
+	MongoClient          *mongo.Client
+	HTTPClient           *http.Client
+	MetricsSink          sink.Sink
 }
 
 func main() {
-	log.Println("Starting service...")
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, nil)))
+
+	if len(os.Args) > 1 && os.Args[1] == "backfill" {
+		runBackfillCommand(os.Args[2:])
+		return
+	}
+
+	slog.Info("Starting service...")
+
+	container, mongoClient, err := buildContainer()
+	if err != nil {
+		slog.Error("Startup failed", "error", err)
+		os.Exit(1)
+	}
+
+	// Setup signal handling
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+
+	// Start the Prometheus /metrics endpoint
+	metricsAddr := os.Getenv("METRICS_ADDR")
+	if metricsAddr == "" {
+		metricsAddr = defaultMetricsAddr
+	}
+	metricsServer := metrics.Serve(metricsAddr)
+	slog.Info("Metrics server listening", "addr", metricsAddr)
+
+	// Start the read API over stored Metrics
+	apiAddr := os.Getenv("API_ADDR")
+	if apiAddr == "" {
+		apiAddr = defaultAPIAddr
+	}
+	store := api.NewMongoStore(mongoClient.Database(container.DatabaseName).Collection(container.MetricsCollection))
+	apiServer := api.Serve(apiAddr, store)
+	slog.Info("Read API listening", "addr", apiAddr)
+
+	// Start cron
+	c := cron.New()
+	_, err = c.AddFunc("*/1 * * * *", container.CalculateMetricAndPushToDB)
+	if err != nil {
+		slog.Error("Failed to schedule cron", "error", err)
+		os.Exit(1)
+	}
+	c.Start()
+	slog.Info("Cron job running every 1 minute")
+
+	<-stop
+	slog.Info("Shutdown signal received")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	c.Stop()
+	slog.Info("Cron stopped")
+
+	if err := metrics.Shutdown(ctx, metricsServer); err != nil {
+		slog.Error("Metrics server shutdown error", "error", err)
+	}
+	if err := apiServer.Shutdown(ctx); err != nil {
+		slog.Error("API server shutdown error", "error", err)
+	}
+	if err := container.MetricsSink.Close(); err != nil {
+		slog.Error("Metrics sink close error", "error", err)
+	}
+
+	if err := mongostore.DisconnectWithTimeout(mongoClient, 10*time.Second); err != nil {
+		slog.Error("Mongo disconnect error", "error", err)
+	} else {
+		slog.Info("Mongo disconnected cleanly")
+	}
+}
 
-	// Load environment
+// buildContainer loads environment configuration, connects to MongoDB, and
+// assembles a ready-to-use Container. It is shared by the cron entrypoint
+// and the backfill CLI subcommand.
+func buildContainer() (*Container, *mongo.Client, error) {
 	if err := godotenv.Load(); err != nil {
-		log.Fatal("Failed to load .env file")
+		return nil, nil, fmt.Errorf("failed to load .env file: %w", err)
 	}
 
 	requiredEnv := []string{"MONGO_URI", "GET_WEIGHT_GRAPH_API", "MONGO_DB", "MetricsCollection", "UpdateLogCollection"}
 	for _, env := range requiredEnv {
 		if os.Getenv(env) == "" {
-			log.Fatalf("Missing required environment variable: %s", env)
+			return nil, nil, fmt.Errorf("missing required environment variable: %s", env)
 		}
 	}
 
-	// Setup MongoDB connection
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -86,77 +187,222 @@ func main() {
 
 	mongoClient, err := mongo.Connect(ctx, clientOpts)
 	if err != nil {
-		log.Fatalf("Mongo connection failed: %v", err)
+		return nil, nil, fmt.Errorf("mongo connection failed: %w", err)
 	}
 
-	// Setup container
-	container := &Container{
-		GraphAPIURL:         os.Getenv("GET_WEIGHT_GRAPH_API"),
-		DatabaseName:        os.Getenv("MONGO_DB"),
+	retryQueueCollection := os.Getenv("RetryQueueCollection")
+	if retryQueueCollection == "" {
+		retryQueueCollection = "retry_queue"
+	}
+	deadLetterCollection := os.Getenv("DeadLetterCollection")
+	if deadLetterCollection == "" {
+		deadLetterCollection = "retry_dead_letter"
+	}
+	maxRetryAttempts := defaultMaxRetryAttempts
+	if v := os.Getenv("MAX_RETRY_ATTEMPTS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			maxRetryAttempts = n
+		}
+	}
+	backfillWindow := defaultBackfillWindow
+	if v := os.Getenv("BACKFILL_WINDOW_MINUTES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			backfillWindow = time.Duration(n) * time.Minute
+		}
+	}
+	metricsTTLDays := 0
+	if v := os.Getenv("METRICS_TTL_DAYS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			metricsTTLDays = n
+		}
+	}
+
+	db := mongoClient.Database(os.Getenv("MONGO_DB"))
+	if err := mongostore.Run(ctx, mongostore.Config{
+		Database:            db,
 		MetricsCollection:   os.Getenv("MetricsCollection"),
 		UpdateLogCollection: os.Getenv("UpdateLogCollection"),
-		MongoClient:         mongoClient,
+		MetricsTTLDays:      metricsTTLDays,
+	}); err != nil {
+		return nil, nil, fmt.Errorf("running mongo migrations: %w", err)
+	}
+
+	metricsCollection := db.Collection(os.Getenv("MetricsCollection"))
+	metricsSink, err := sink.BuildFromEnv(ctx, metricsCollection)
+	if err != nil {
+		return nil, nil, fmt.Errorf("building metrics sink: %w", err)
+	}
+
+	container := &Container{
+		GraphAPIURL:          os.Getenv("GET_WEIGHT_GRAPH_API"),
+		DatabaseName:         os.Getenv("MONGO_DB"),
+		MetricsCollection:    os.Getenv("MetricsCollection"),
+		UpdateLogCollection:  os.Getenv("UpdateLogCollection"),
+		RetryQueueCollection: retryQueueCollection,
+		DeadLetterCollection: deadLetterCollection,
+		MaxRetryAttempts:     maxRetryAttempts,
+		BackfillWindow:       backfillWindow,
+		MongoClient:          mongoClient,
 		HTTPClient: &http.Client{
 			Timeout: 15 * time.Second,
 		},
+		MetricsSink: metricsSink,
 	}
 
-	// Setup signal handling
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	return container, mongoClient, nil
+}
 
-	// Start cron
-	c := cron.New()
-	_, err = c.AddFunc("*/1 * * * *", container.CalculateMetricAndPushToDB)
+// newFetchID returns a short random hex identifier correlating all log
+// records emitted by a single CalculateMetricAndPushToDB run.
+func newFetchID() string {
+	buf := make([]byte, 4)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+func (c *Container) CalculateMetricAndPushToDB() {
+	fetchID := newFetchID()
+	logger := slog.With("fetch_id", fetchID)
+	logger.Info("Starting scheduled metric fetch...")
+
+	c.drainRetryQueue(logger)
+
+	end := time.Now().UnixMicro()
+
+	start, skip := c.getStartTime(end, logger)
+	if skip {
+		logger.Info("Skipping execution based on start time")
+		return
+	}
+
+	if time.Duration(end-start)*time.Microsecond > c.BackfillWindow {
+		logger.Info("Gap exceeds backfill window; splitting into sub-windows", "gap", time.Duration(end-start)*time.Microsecond)
+		if err := c.Backfill(start, end, c.BackfillWindow, defaultBackfillConcurrencyTick, logger); err != nil {
+			logger.Error("Automatic backfill failed", "error", err)
+		}
+		return
+	}
+
+	c.processWindow(start, end, 0, logger)
+}
+
+// processWindow fetches and saves a single window, retrying both steps
+// in-process with backoff before handing the window off to the durable
+// retry queue so no tick produces a permanent gap.
+func (c *Container) processWindow(start, end int64, priorAttempts int, logger *slog.Logger) {
+	logger = logger.With("start", start, "end", end)
+
+	var graphData GraphData
+	fetchStart := time.Now()
+	err := retry.Default.Do(context.Background(), perTickAttempts, func() error {
+		var fetchErr error
+		graphData, fetchErr = c.fetchGraphData(start, end, logger)
+		return fetchErr
+	})
+	metrics.FetchDuration.Observe(time.Since(fetchStart).Seconds())
 	if err != nil {
-		log.Fatalf("Failed to schedule cron: %v", err)
+		metrics.FetchTotal.WithLabelValues("error").Inc()
+		logger.Error("Error fetching graph data", "error", err)
+		c.enqueueRetry(start, end, priorAttempts, err, logger)
+		return
 	}
-	c.Start()
-	log.Println("Cron job running every 1 minute")
+	metrics.FetchTotal.WithLabelValues("success").Inc()
+	metrics.GraphNodes.Set(float64(len(graphData.Nodes)))
+	metrics.GraphEdges.Set(float64(len(graphData.Edges)))
+	metrics.ObserveGap(start, end)
 
-	<-stop
-	log.Println("Shutdown signal received")
+	logger = logger.With("nodes", len(graphData.Nodes), "edges", len(graphData.Edges))
 
-	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	c.Stop()
-	log.Println("Cron stopped")
+	if len(graphData.Nodes) == 0 || len(graphData.Edges) == 0 {
+		logger.Info("Empty graph data — skipping DB insert")
+		return
+	}
 
-	if err := mongoClient.Disconnect(ctx); err != nil {
-		log.Printf("Mongo disconnect error: %v", err)
-	} else {
-		log.Println("Mongo disconnected cleanly")
+	metricsDoc := Metrics{StartTime: start, EndTime: end, Data: graphData}
+	err = retry.Default.Do(context.Background(), perTickAttempts, func() error {
+		return c.saveMetrics(metricsDoc, logger)
+	})
+	if err != nil {
+		logger.Error("Failed to save metrics", "error", err)
+		c.enqueueRetry(start, end, priorAttempts, err, logger)
 	}
 }
 
-func (c *Container) CalculateMetricAndPushToDB() {
-	log.Println("Starting scheduled metric fetch...")
+// enqueueRetry persists a failed window to the retry queue, or to the dead
+// letter collection once it has exhausted MaxRetryAttempts. Either way, the
+// window's outcome is now durably recorded, so the high-water mark advances
+// past it — otherwise getStartTime would keep handing the same interval back
+// to the next tick's processWindow, double-covering whatever the retry queue
+// is already responsible for.
+func (c *Container) enqueueRetry(start, end int64, priorAttempts int, cause error, logger *slog.Logger) {
+	attempts := priorAttempts + 1
 
-	end := time.Now().UnixMicro()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-	start, skip := c.getStartTime(end)
-	if skip {
-		log.Println("Skipping execution based on start time")
+	db := c.MongoClient.Database(c.DatabaseName)
+
+	if attempts >= c.MaxRetryAttempts {
+		logger.Error("Window exhausted retry attempts; moving to dead letter", "attempts", attempts)
+		deadLetter := retry.Window{Start: start, End: end, Attempts: attempts, LastError: cause.Error()}
+		if _, err := db.Collection(c.DeadLetterCollection).InsertOne(ctx, deadLetter); err != nil {
+			logger.Error("Failed to write dead letter", "error", err)
+		}
+		if err := c.advanceUpdateLog(end); err != nil {
+			logger.Error("Failed to advance update log past dead-lettered window", "error", err)
+		}
 		return
 	}
 
-	graphData, err := c.fetchGraphData(start, end)
+	window := retry.Window{
+		Start:       start,
+		End:         end,
+		Attempts:    attempts,
+		NextAttempt: time.Now().Add(retry.Default.Next(attempts)).UnixMicro(),
+		LastError:   cause.Error(),
+	}
+	if _, err := db.Collection(c.RetryQueueCollection).InsertOne(ctx, window); err != nil {
+		logger.Error("Failed to enqueue retry", "error", err)
+	}
+	if err := c.advanceUpdateLog(end); err != nil {
+		logger.Error("Failed to advance update log past enqueued retry window", "error", err)
+	}
+}
+
+// drainRetryQueue reprocesses any due windows before the normal tick runs,
+// so a backlog of transient failures is worked off instead of growing.
+func (c *Container) drainRetryQueue(logger *slog.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	col := c.MongoClient.Database(c.DatabaseName).Collection(c.RetryQueueCollection)
+	cur, err := col.Find(ctx, bson.M{"next_attempt": bson.M{"$lte": time.Now().UnixMicro()}})
 	if err != nil {
-		log.Printf("Error fetching graph data: %v", err)
+		logger.Error("Failed to query retry queue", "error", err)
 		return
 	}
+	defer cur.Close(ctx)
 
-	if len(graphData.Nodes) == 0 || len(graphData.Edges) == 0 {
-		log.Println("Empty graph data â€” skipping DB insert")
+	var due []retry.Window
+	if err := cur.All(ctx, &due); err != nil {
+		logger.Error("Failed to decode retry queue", "error", err)
 		return
 	}
 
-	if err := c.saveMetrics(Metrics{StartTime: start, EndTime: end, Data: graphData}); err != nil {
-		log.Printf("Failed to save metrics: %v", err)
+	for _, w := range due {
+		logger.Info("Draining retry queue window", "start", w.Start, "end", w.End, "attempt", w.Attempts+1)
+		deleteCtx, deleteCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_, err := col.DeleteOne(deleteCtx, bson.M{"start_time": w.Start, "end_time": w.End})
+		deleteCancel()
+		if err != nil {
+			logger.Error("Failed to remove drained window from queue", "start", w.Start, "end", w.End, "error", err)
+			continue
+		}
+		c.processWindow(w.Start, w.End, w.Attempts, logger)
 	}
 }
 
-func (c *Container) getStartTime(end int64) (start int64, skip bool) {
+func (c *Container) getStartTime(end int64, logger *slog.Logger) (start int64, skip bool) {
 	const maxAllowedGap = 7 * 24 * 60 * 60 * 1_000_000 // 7 days in microseconds
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -168,24 +414,24 @@ func (c *Container) getStartTime(end int64) (start int64, skip bool) {
 	err := col.FindOne(ctx, bson.M{}, opts).Decode(&last)
 
 	if err == nil {
-		log.Printf("Last fetch time: %v", last.LastFetchTime)
+		logger.Info("Last fetch time found", "last_fetch_time", last.LastFetchTime)
 		start = last.LastFetchTime
 	} else {
-		log.Println("No last fetch time found; using default 15 mins")
+		logger.Info("No last fetch time found; using default 15 mins")
 		start = end - 15*60*1_000_000
 	}
 
 	if end-start > maxAllowedGap {
 		start = end - maxAllowedGap
-		log.Printf("Capped start time to max allowed gap: %v", start)
+		logger.Info("Capped start time to max allowed gap", "start", start)
 	}
 
 	return start, false
 }
 
-func (c *Container) fetchGraphData(start, end int64) (GraphData, error) {
+func (c *Container) fetchGraphData(start, end int64, logger *slog.Logger) (GraphData, error) {
 	url := fmt.Sprintf(c.GraphAPIURL, start, end)
-	log.Printf("Fetching graph from %s", url)
+	logger.Info("Fetching graph data", "url", url)
 
 	resp, err := c.HTTPClient.Get(url)
 	if err != nil {
@@ -211,22 +457,73 @@ func (c *Container) fetchGraphData(start, end int64) (GraphData, error) {
 	return response.Data, nil
 }
 
-func (c *Container) saveMetrics(metrics Metrics) error {
+func (c *Container) saveMetrics(metricsDoc Metrics, logger *slog.Logger) error {
+	if err := c.insertMetricsDoc(metricsDoc); err != nil {
+		return err
+	}
+	logger.Info("Metrics inserted")
+
+	if err := c.advanceUpdateLog(metricsDoc.EndTime); err != nil {
+		return err
+	}
+	logger.Info("Last fetch time updated")
+	return nil
+}
+
+// insertMetricsDoc writes a single snapshot through the configured sink,
+// without touching UpdateLog. Used directly by the backfill pipeline, which
+// advances UpdateLog to the end of its range once every sub-window has been
+// stored, queued for retry or dead-lettered; it does not wait for a
+// contiguous prefix of stored windows.
+func (c *Container) insertMetricsDoc(metricsDoc Metrics) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	db := c.MongoClient.Database(c.DatabaseName)
-
-	_, err := db.Collection(c.MetricsCollection).InsertOne(ctx, metrics)
+	insertStart := time.Now()
+	err := c.MetricsSink.Write(ctx, toGraphSnapshot(metricsDoc))
+	metrics.MongoInsertDuration.Observe(time.Since(insertStart).Seconds())
 	if err != nil {
-		return fmt.Errorf("inserting metrics failed: %w", err)
+		return fmt.Errorf("writing metrics to sink failed: %w", err)
+	}
+	return nil
+}
+
+// toGraphSnapshot adapts the collector's internal Metrics document to the
+// sink/api packages' decoupled wire type.
+func toGraphSnapshot(m Metrics) api.GraphSnapshot {
+	snapshot := api.GraphSnapshot{
+		StartTime: m.StartTime,
+		EndTime:   m.EndTime,
+		StoredAt:  time.Now().UTC(),
+	}
+	for _, n := range m.Data.Nodes {
+		snapshot.Data.Nodes = append(snapshot.Data.Nodes, api.Node{
+			ID:                 n.ID,
+			AbsoluteImportance: n.AbsoluteImportance,
+			AbsoluteDependence: n.AbsoluteDependence,
+		})
+	}
+	for _, e := range m.Data.Edges {
+		snapshot.Data.Edges = append(snapshot.Data.Edges, api.Edge{
+			Source:      e.Source,
+			Target:      e.Target,
+			Latency:     e.Latency,
+			Frequency:   e.Frequency,
+			CoExecution: e.CoExecution,
+		})
 	}
-	log.Println("Metrics inserted")
+	return snapshot
+}
+
+// advanceUpdateLog records lastFetchTime as the new high-water mark.
+func (c *Container) advanceUpdateLog(lastFetchTime int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-	_, err = db.Collection(c.UpdateLogCollection).InsertOne(ctx, UpdateLog{LastFetchTime: metrics.EndTime})
+	_, err := c.MongoClient.Database(c.DatabaseName).Collection(c.UpdateLogCollection).
+		InsertOne(ctx, UpdateLog{LastFetchTime: lastFetchTime})
 	if err != nil {
 		return fmt.Errorf("updating fetch time failed: %w", err)
 	}
-	log.Println("Last fetch time updated")
 	return nil
 }
@@ -0,0 +1,92 @@
+// Package metrics holds the collector's Prometheus instrumentation and the
+// embedded HTTP server that exposes it.
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	// FetchTotal counts fetched windows by final outcome ("success" or
+	// "error"), once per window after its in-process retries.
+	FetchTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "collector_fetch_total",
+		Help: "Total number of upstream graph window fetches after in-process retries, by status.",
+	}, []string{"status"})
+
+	// FetchDuration tracks how long fetching one window takes, including
+	// its in-process retries and the backoff waits between them.
+	FetchDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "collector_fetch_duration_seconds",
+		Help:    "Duration of upstream graph window fetches, including retries, in seconds.",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	// GraphNodes is the node count of the most recently fetched graph.
+	GraphNodes = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "collector_graph_nodes",
+		Help: "Number of nodes in the most recently fetched graph.",
+	})
+
+	// GraphEdges is the edge count of the most recently fetched graph.
+	GraphEdges = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "collector_graph_edges",
+		Help: "Number of edges in the most recently fetched graph.",
+	})
+
+	// MongoInsertDuration tracks how long each write through the configured
+	// metrics sink takes in insertMetricsDoc (a Mongo upsert under the
+	// default "mongo" sink).
+	MongoInsertDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "collector_mongo_insert_duration_seconds",
+		Help:    "Duration of metrics sink writes (Mongo upserts by default) in seconds.",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	// GapSeconds is the size (end-start) of the most recently processed window.
+	GapSeconds = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "collector_gap_seconds",
+		Help: "Size in seconds of the most recently processed fetch window.",
+	})
+)
+
+// ObserveGap records the window size, in seconds, for a processed tick given
+// start and end as unix microseconds.
+func ObserveGap(startMicro, endMicro int64) {
+	GapSeconds.Set(float64(endMicro-startMicro) / 1e6)
+}
+
+// Serve starts the Prometheus /metrics endpoint on addr and returns the
+// underlying *http.Server so the caller can shut it down gracefully. It does
+// not block; ListenAndServe runs in its own goroutine.
+func Serve(addr string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	srv := &http.Server{
+		Addr:         addr,
+		Handler:      mux,
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.Error("metrics server stopped", "error", err)
+		}
+	}()
+
+	return srv
+}
+
+// Shutdown gracefully stops srv, bounded by ctx.
+func Shutdown(ctx context.Context, srv *http.Server) error {
+	return srv.Shutdown(ctx)
+}
@@ -0,0 +1,73 @@
+// Package retry provides a small exponential-backoff helper shared by the
+// fetch and save paths of the collector, plus the types used to persist a
+// failed window so it can be retried on a later tick.
+package retry
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Backoff describes an exponential backoff schedule with jitter.
+type Backoff struct {
+	// Duration is the delay before the first retry.
+	Duration time.Duration
+	// Factor is the multiplier applied to Duration for each subsequent attempt.
+	Factor float64
+	// Jitter is the fraction of the computed delay (0-1) randomized in either direction.
+	Jitter float64
+	// Cap is the maximum delay returned, regardless of attempt count.
+	Cap time.Duration
+}
+
+// Default mirrors the schedule used by the logmower-shipper retry client:
+// 2s initial, 1.5x factor, 10% jitter, capped at 30s.
+var Default = Backoff{
+	Duration: 2 * time.Second,
+	Factor:   1.5,
+	Jitter:   0.1,
+	Cap:      30 * time.Second,
+}
+
+// Next returns the delay to wait after failed attempt n (0-indexed), i.e.
+// before retry n+1; Next(0) is the delay before the first retry.
+func (b Backoff) Next(attempt int) time.Duration {
+	d := float64(b.Duration) * math.Pow(b.Factor, float64(attempt))
+	if cap := float64(b.Cap); b.Cap > 0 && d > cap {
+		d = cap
+	}
+	if b.Jitter > 0 {
+		delta := d * b.Jitter
+		d += (rand.Float64()*2 - 1) * delta
+	}
+	if cap := float64(b.Cap); b.Cap > 0 && d > cap {
+		d = cap
+	}
+	if d < 0 {
+		d = 0
+	}
+	return time.Duration(d)
+}
+
+// Do calls fn until it succeeds or attempts is exhausted, sleeping between
+// attempts according to b. It returns the last error if every attempt fails,
+// or nil as soon as fn succeeds. ctx cancellation aborts the wait early.
+func (b Backoff) Do(ctx context.Context, attempts int, fn func() error) error {
+	var err error
+	for i := 0; i < attempts; i++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if i == attempts-1 {
+			break
+		}
+		select {
+		case <-time.After(b.Next(i)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return err
+}
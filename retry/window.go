@@ -0,0 +1,11 @@
+package retry
+
+// Window is a time range that failed to fetch and/or save, persisted so a
+// later tick can pick it back up instead of the gap being lost forever.
+type Window struct {
+	Start       int64  `bson:"start_time"`
+	End         int64  `bson:"end_time"`
+	Attempts    int    `bson:"attempts"`
+	NextAttempt int64  `bson:"next_attempt"` // unix micros; due when <= now
+	LastError   string `bson:"last_error"`
+}
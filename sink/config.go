@@ -0,0 +1,80 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// BuildFromEnv constructs the configured sink(s) from the SINKS env var, a
+// comma-separated list such as "mongo,otlp,kafka" (default "mongo"). Each
+// named sink reads its own sub-config from the environment:
+//
+//	mongo:   uses metricsCollection, the collector's existing Metrics collection
+//	otlp:    OTLP_ENDPOINT (host:port)
+//	kafka:   KAFKA_BROKERS (comma-separated), KAFKA_TOPIC
+//	parquet: PARQUET_DIR
+//
+// A single configured sink is returned as-is; more than one is wrapped in a
+// FanOut.
+func BuildFromEnv(ctx context.Context, metricsCollection *mongo.Collection) (Sink, error) {
+	spec := os.Getenv("SINKS")
+	if spec == "" {
+		spec = "mongo"
+	}
+
+	named := make(map[string]Sink)
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		s, err := buildOne(ctx, name, metricsCollection)
+		if err != nil {
+			return nil, err
+		}
+		named[name] = s
+	}
+
+	if len(named) == 0 {
+		return nil, fmt.Errorf("SINKS resolved to no sinks")
+	}
+	if len(named) == 1 {
+		for _, s := range named {
+			return s, nil
+		}
+	}
+	return NewFanOut(named), nil
+}
+
+func buildOne(ctx context.Context, name string, metricsCollection *mongo.Collection) (Sink, error) {
+	switch name {
+	case "mongo":
+		return NewMongoSink(metricsCollection), nil
+	case "otlp":
+		endpoint := os.Getenv("OTLP_ENDPOINT")
+		if endpoint == "" {
+			return nil, fmt.Errorf("OTLP_ENDPOINT is required for the otlp sink")
+		}
+		return NewOTLPSink(ctx, endpoint)
+	case "kafka":
+		brokers := os.Getenv("KAFKA_BROKERS")
+		topic := os.Getenv("KAFKA_TOPIC")
+		if brokers == "" || topic == "" {
+			return nil, fmt.Errorf("KAFKA_BROKERS and KAFKA_TOPIC are required for the kafka sink")
+		}
+		return NewKafkaSink(strings.Split(brokers, ","), topic), nil
+	case "parquet":
+		dir := os.Getenv("PARQUET_DIR")
+		if dir == "" {
+			return nil, fmt.Errorf("PARQUET_DIR is required for the parquet sink")
+		}
+		return NewParquetSink(dir), nil
+	default:
+		return nil, fmt.Errorf("unknown sink %q", name)
+	}
+}
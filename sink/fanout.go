@@ -0,0 +1,71 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/coupling-index-monitor/metric-n-trace-collector/api"
+	"github.com/coupling-index-monitor/metric-n-trace-collector/retry"
+)
+
+// fanOutAttempts bounds the in-process retries given to each sink before
+// its failure is folded into the fan-out's aggregate error.
+const fanOutAttempts = 3
+
+// namedSink pairs a Sink with a name for error reporting.
+type namedSink struct {
+	name string
+	sink Sink
+}
+
+// FanOut writes a snapshot to every configured sink concurrently, retrying
+// each with backoff independently so one sink's outage doesn't block or
+// poison the others. Write returns a joined error listing every sink that
+// ultimately failed; callers that enqueue to the durable retry queue on any
+// error will still retry the whole window, writing it again to the sinks
+// that already succeeded: the mongo and parquet sinks overwrite their
+// earlier output, while the kafka and otlp sinks emit it a second time.
+type FanOut struct {
+	sinks []namedSink
+}
+
+// NewFanOut composes sinks, keyed by name (e.g. "mongo", "otlp") for error
+// messages.
+func NewFanOut(sinks map[string]Sink) *FanOut {
+	f := &FanOut{}
+	for name, s := range sinks {
+		f.sinks = append(f.sinks, namedSink{name: name, sink: s})
+	}
+	return f
+}
+
+func (f *FanOut) Write(ctx context.Context, snapshot api.GraphSnapshot) error {
+	errs := make([]error, len(f.sinks))
+	var wg sync.WaitGroup
+	for i, ns := range f.sinks {
+		wg.Add(1)
+		go func(i int, ns namedSink) {
+			defer wg.Done()
+			err := retry.Default.Do(ctx, fanOutAttempts, func() error {
+				return ns.sink.Write(ctx, snapshot)
+			})
+			if err != nil {
+				errs[i] = fmt.Errorf("sink %s: %w", ns.name, err)
+			}
+		}(i, ns)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+func (f *FanOut) Close() error {
+	var errs []error
+	for _, ns := range f.sinks {
+		if err := ns.sink.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("sink %s: %w", ns.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
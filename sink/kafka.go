@@ -0,0 +1,49 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/coupling-index-monitor/metric-n-trace-collector/api"
+	kafka "github.com/segmentio/kafka-go"
+)
+
+// KafkaSink publishes each snapshot as a JSON record to a Kafka topic, keyed
+// by its end time so consumers can partition/compact on it.
+type KafkaSink struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaSink creates a sink publishing to topic on the given brokers.
+func NewKafkaSink(brokers []string, topic string) *KafkaSink {
+	return &KafkaSink{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Topic:        topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+		},
+	}
+}
+
+func (s *KafkaSink) Write(ctx context.Context, snapshot api.GraphSnapshot) error {
+	value, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("marshaling snapshot for kafka sink: %w", err)
+	}
+
+	msg := kafka.Message{
+		Key:   []byte(strconv.FormatInt(snapshot.EndTime, 10)),
+		Value: value,
+	}
+	if err := s.writer.WriteMessages(ctx, msg); err != nil {
+		return fmt.Errorf("kafka sink publish: %w", err)
+	}
+	return nil
+}
+
+func (s *KafkaSink) Close() error {
+	return s.writer.Close()
+}
@@ -0,0 +1,37 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/coupling-index-monitor/metric-n-trace-collector/api"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoSink is the collector's original persistence path: one document per
+// snapshot in the configured Metrics collection.
+type MongoSink struct {
+	Collection *mongo.Collection
+}
+
+// NewMongoSink wraps col as a Sink.
+func NewMongoSink(col *mongo.Collection) *MongoSink {
+	return &MongoSink{Collection: col}
+}
+
+// Write upserts on {start_time, end_time} rather than inserting, so a
+// redelivered snapshot (retry queue, fan-out, re-backfill) overwrites the
+// existing document instead of duplicating it.
+func (s *MongoSink) Write(ctx context.Context, snapshot api.GraphSnapshot) error {
+	filter := bson.M{"start_time": snapshot.StartTime, "end_time": snapshot.EndTime}
+	if _, err := s.Collection.ReplaceOne(ctx, filter, snapshot, options.Replace().SetUpsert(true)); err != nil {
+		return fmt.Errorf("mongo sink upsert: %w", err)
+	}
+	return nil
+}
+
+func (s *MongoSink) Close() error {
+	return nil
+}
@@ -0,0 +1,75 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/coupling-index-monitor/metric-n-trace-collector/api"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// OTLPSink exports each snapshot's edges and node scores as OTel metrics to
+// an OTLP gRPC endpoint, with source/target carried as attributes so the
+// graph topology survives the trip (the closest fit to "traces" without a
+// synthetic span per edge).
+type OTLPSink struct {
+	exporter *otlpmetricgrpc.Exporter
+}
+
+// NewOTLPSink dials endpoint (host:port, e.g. "otel-collector:4317").
+func NewOTLPSink(ctx context.Context, endpoint string) (*OTLPSink, error) {
+	exp, err := otlpmetricgrpc.New(ctx,
+		otlpmetricgrpc.WithEndpoint(endpoint),
+		otlpmetricgrpc.WithInsecure(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("dialing otlp endpoint %s: %w", endpoint, err)
+	}
+	return &OTLPSink{exporter: exp}, nil
+}
+
+func (s *OTLPSink) Write(ctx context.Context, snapshot api.GraphSnapshot) error {
+	ts := time.UnixMicro(snapshot.EndTime)
+
+	var edgeLatency, edgeFrequency, edgeCoExecution []metricdata.DataPoint[float64]
+	for _, e := range snapshot.Data.Edges {
+		attrs := attribute.NewSet(
+			attribute.String("source", e.Source),
+			attribute.String("target", e.Target),
+		)
+		edgeLatency = append(edgeLatency, metricdata.DataPoint[float64]{Attributes: attrs, Time: ts, Value: e.Latency})
+		edgeFrequency = append(edgeFrequency, metricdata.DataPoint[float64]{Attributes: attrs, Time: ts, Value: float64(e.Frequency)})
+		edgeCoExecution = append(edgeCoExecution, metricdata.DataPoint[float64]{Attributes: attrs, Time: ts, Value: e.CoExecution})
+	}
+
+	var nodeImportance, nodeDependence []metricdata.DataPoint[float64]
+	for _, n := range snapshot.Data.Nodes {
+		attrs := attribute.NewSet(attribute.String("node_id", n.ID))
+		nodeImportance = append(nodeImportance, metricdata.DataPoint[float64]{Attributes: attrs, Time: ts, Value: float64(n.AbsoluteImportance)})
+		nodeDependence = append(nodeDependence, metricdata.DataPoint[float64]{Attributes: attrs, Time: ts, Value: float64(n.AbsoluteDependence)})
+	}
+
+	metrics := []metricdata.Metrics{
+		{Name: "collector.edge.latency", Data: metricdata.Gauge[float64]{DataPoints: edgeLatency}},
+		{Name: "collector.edge.frequency", Data: metricdata.Gauge[float64]{DataPoints: edgeFrequency}},
+		{Name: "collector.edge.co_execution", Data: metricdata.Gauge[float64]{DataPoints: edgeCoExecution}},
+		{Name: "collector.node.absolute_importance", Data: metricdata.Gauge[float64]{DataPoints: nodeImportance}},
+		{Name: "collector.node.absolute_dependence", Data: metricdata.Gauge[float64]{DataPoints: nodeDependence}},
+	}
+
+	resourceMetrics := &metricdata.ResourceMetrics{
+		ScopeMetrics: []metricdata.ScopeMetrics{{Metrics: metrics}},
+	}
+
+	if err := s.exporter.Export(ctx, resourceMetrics); err != nil {
+		return fmt.Errorf("otlp sink export: %w", err)
+	}
+	return nil
+}
+
+func (s *OTLPSink) Close() error {
+	return s.exporter.Shutdown(context.Background())
+}
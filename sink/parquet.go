@@ -0,0 +1,83 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/coupling-index-monitor/metric-n-trace-collector/api"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// edgeRecord is one flattened, Parquet-friendly row: a single edge plus the
+// window it was observed in. Edges are flattened rather than nested because
+// Parquet's columnar layout favors flat, repeated rows for offline querying.
+type edgeRecord struct {
+	StartTime   int64   `parquet:"name=start_time, type=INT64"`
+	EndTime     int64   `parquet:"name=end_time, type=INT64"`
+	Source      string  `parquet:"name=source, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Target      string  `parquet:"name=target, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Latency     float64 `parquet:"name=latency, type=DOUBLE"`
+	Frequency   int64   `parquet:"name=frequency, type=INT64"`
+	CoExecution float64 `parquet:"name=co_execution, type=DOUBLE"`
+}
+
+// ParquetSink writes each snapshot to its own Parquet file under Dir, for
+// offline analysis. It is not meant to be queried live.
+type ParquetSink struct {
+	Dir string
+}
+
+// NewParquetSink writes files into dir, creating it if necessary.
+func NewParquetSink(dir string) *ParquetSink {
+	return &ParquetSink{Dir: dir}
+}
+
+func (s *ParquetSink) Write(_ context.Context, snapshot api.GraphSnapshot) error {
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return fmt.Errorf("creating parquet dir %s: %w", s.Dir, err)
+	}
+
+	path := filepath.Join(s.Dir, strconv.FormatInt(snapshot.StartTime, 10)+"_"+strconv.FormatInt(snapshot.EndTime, 10)+".parquet")
+
+	fw, err := local.NewLocalFileWriter(path)
+	if err != nil {
+		return fmt.Errorf("opening parquet file %s: %w", path, err)
+	}
+
+	pw, err := writer.NewParquetWriter(fw, new(edgeRecord), 4)
+	if err != nil {
+		fw.Close()
+		return fmt.Errorf("creating parquet writer for %s: %w", path, err)
+	}
+
+	for _, e := range snapshot.Data.Edges {
+		row := edgeRecord{
+			StartTime:   snapshot.StartTime,
+			EndTime:     snapshot.EndTime,
+			Source:      e.Source,
+			Target:      e.Target,
+			Latency:     e.Latency,
+			Frequency:   int64(e.Frequency),
+			CoExecution: e.CoExecution,
+		}
+		if err := pw.Write(row); err != nil {
+			pw.WriteStop()
+			fw.Close()
+			return fmt.Errorf("writing parquet row: %w", err)
+		}
+	}
+
+	if err := pw.WriteStop(); err != nil {
+		fw.Close()
+		return fmt.Errorf("finalizing parquet file %s: %w", path, err)
+	}
+	return fw.Close()
+}
+
+func (s *ParquetSink) Close() error {
+	return nil
+}
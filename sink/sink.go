@@ -0,0 +1,19 @@
+// Package sink decouples metric persistence from MongoDB: a Sink is anything
+// that can durably accept a graph snapshot, and Container writes through
+// whichever Sink (or fan-out of sinks) is configured.
+package sink
+
+import (
+	"context"
+
+	"github.com/coupling-index-monitor/metric-n-trace-collector/api"
+)
+
+// Sink persists a single graph snapshot.
+type Sink interface {
+	// Write durably stores snapshot. Implementations should treat Write as
+	// idempotent where possible, since callers may retry on error.
+	Write(ctx context.Context, snapshot api.GraphSnapshot) error
+	// Close releases any underlying connections.
+	Close() error
+}
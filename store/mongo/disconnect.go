@@ -0,0 +1,16 @@
+package mongostore
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// DisconnectWithTimeout disconnects client, bounding the call instead of
+// leaving shutdown at the mercy of a caller-supplied context.
+func DisconnectWithTimeout(client *mongo.Client, d time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return client.Disconnect(ctx)
+}
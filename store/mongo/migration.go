@@ -0,0 +1,46 @@
+// Package mongostore manages the collector's MongoDB lifecycle: schema
+// migrations (indexes, retention) run once at startup, plus connection
+// helpers shared by every entrypoint.
+package mongostore
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Version identifies a migration, e.g. "1.0.0".
+type Version string
+
+// ErrSkipRecording is returned by Up when the migration declined to act on
+// the current Config (e.g. a feature it provisions is disabled) and should
+// therefore not be recorded as applied — if Config changes on a later
+// startup such that the migration would now act, Run must try it again.
+var ErrSkipRecording = errors.New("migration: skip recording as applied")
+
+// Config carries the collection names a migration needs; collection names
+// are operator-configured, so they can't be hardcoded into the migrations
+// that reference them.
+type Config struct {
+	Database            *mongo.Database
+	MetricsCollection   string
+	UpdateLogCollection string
+	// MetricsTTLDays is the automatic retention window for the metrics
+	// collection; 0 disables the TTL index.
+	MetricsTTLDays int
+}
+
+// Migration is one forward-only schema change.
+type Migration interface {
+	Version() Version
+	Up(ctx context.Context, cfg Config) error
+}
+
+// registry holds every migration in application order, populated by each
+// migration file's init().
+var registry []Migration
+
+func register(m Migration) {
+	registry = append(registry, m)
+}
@@ -0,0 +1,39 @@
+package mongostore
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func init() {
+	register(migration100{})
+}
+
+// migration100 creates the indexes getStartTime's last_fetch_time sort and
+// the read API's window queries rely on; without them both do a full
+// collection scan.
+type migration100 struct{}
+
+func (migration100) Version() Version { return "1.0.0" }
+
+func (migration100) Up(ctx context.Context, cfg Config) error {
+	updateLog := cfg.Database.Collection(cfg.UpdateLogCollection)
+	if _, err := updateLog.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "last_fetch_time", Value: -1}},
+	}); err != nil {
+		return fmt.Errorf("creating last_fetch_time index: %w", err)
+	}
+
+	metricsCol := cfg.Database.Collection(cfg.MetricsCollection)
+	if _, err := metricsCol.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "end_time", Value: -1}}},
+		{Keys: bson.D{{Key: "start_time", Value: 1}, {Key: "end_time", Value: 1}}},
+	}); err != nil {
+		return fmt.Errorf("creating metrics indexes: %w", err)
+	}
+
+	return nil
+}
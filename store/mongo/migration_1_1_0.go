@@ -0,0 +1,40 @@
+package mongostore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func init() {
+	register(migration110{})
+}
+
+// migration110 adds a TTL index on stored_at so metrics older than
+// MetricsTTLDays are reaped automatically. It declines (via ErrSkipRecording)
+// when retention is disabled (MetricsTTLDays <= 0), so that enabling
+// retention on a later startup still creates the index instead of finding
+// 1.1.0 already marked applied.
+type migration110 struct{}
+
+func (migration110) Version() Version { return "1.1.0" }
+
+func (migration110) Up(ctx context.Context, cfg Config) error {
+	if cfg.MetricsTTLDays <= 0 {
+		return ErrSkipRecording
+	}
+
+	ttl := time.Duration(cfg.MetricsTTLDays) * 24 * time.Hour
+	_, err := cfg.Database.Collection(cfg.MetricsCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "stored_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(ttl.Seconds())),
+	})
+	if err != nil {
+		return fmt.Errorf("creating metrics TTL index: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package mongostore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// migrationsCollection tracks which Versions have already been applied.
+const migrationsCollection = "schema_migrations"
+
+// Run applies every registered migration not yet recorded as applied, in
+// registration order, and records each as it completes. It is safe to call
+// on every startup.
+func Run(ctx context.Context, cfg Config) error {
+	col := cfg.Database.Collection(migrationsCollection)
+
+	cur, err := col.Find(ctx, bson.M{})
+	if err != nil {
+		return fmt.Errorf("loading applied migrations: %w", err)
+	}
+	var applied []struct {
+		Version Version `bson:"version"`
+	}
+	if err := cur.All(ctx, &applied); err != nil {
+		return fmt.Errorf("decoding applied migrations: %w", err)
+	}
+
+	done := make(map[Version]bool, len(applied))
+	for _, a := range applied {
+		done[a.Version] = true
+	}
+
+	for _, m := range registry {
+		if done[m.Version()] {
+			continue
+		}
+		err := m.Up(ctx, cfg)
+		if err != nil && !errors.Is(err, ErrSkipRecording) {
+			return fmt.Errorf("migration %s: %w", m.Version(), err)
+		}
+		if err == nil {
+			if _, err := col.InsertOne(ctx, bson.M{"version": m.Version()}); err != nil {
+				return fmt.Errorf("recording migration %s: %w", m.Version(), err)
+			}
+		}
+	}
+	return nil
+}